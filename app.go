@@ -2,43 +2,57 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
+	"sync"
 	"time"
 
+	"github.com/brxyxn/go_mps_redcage/config"
+	"github.com/brxyxn/go_mps_redcage/db"
+	"github.com/brxyxn/go_mps_redcage/events"
 	"github.com/brxyxn/go_mps_redcage/handlers"
+	"github.com/brxyxn/go_mps_redcage/metrics"
+	"github.com/brxyxn/go_mps_redcage/migrations"
 	u "github.com/brxyxn/go_mps_redcage/utils"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/gorilla/mux"
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type App struct {
 	Router   *mux.Router
-	db       *sql.DB
+	db       *pgxpool.Pool
 	l        *log.Logger
-	bindAddr string
+	cfg      *config.Config
+	listener *events.Listener
+	wg       sync.WaitGroup
 }
 
 func (a *App) initRoutes() {
-	h := handlers.NewHandlers(a.db, a.l)
+	h := handlers.NewHandlers(a.db, a.l, a.cfg.DBAcquireTimeout)
 	// Client routes
-	a.Router.HandleFunc("/api/v1/clients", h.CreateClient).Methods("POST")
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}", h.GetClient).Methods("GET")
+	a.Router.HandleFunc("/api/v1/clients", metrics.Middleware("/api/v1/clients", h.CreateClient)).Methods("POST")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}", metrics.Middleware("/api/v1/clients/{client_id}", h.GetClient)).Methods("GET")
 
 	// Account routes
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts", h.GetAccounts).Methods("GET")
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts", h.CreateAccount).Methods("POST")
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}", h.GetAccount).Methods("GET")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts", metrics.Middleware("/api/v1/clients/{client_id}/accounts", h.GetAccounts)).Methods("GET")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts", metrics.Middleware("/api/v1/clients/{client_id}/accounts", h.CreateAccount)).Methods("POST")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}", metrics.Middleware("/api/v1/clients/{client_id}/accounts/{account_id}", h.GetAccount)).Methods("GET")
 
 	// Transaction routes
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}/transactions", h.GetTransactions).Methods("GET")
-	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}/transactions", h.CreateTransaction).Methods("POST")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}/transactions", metrics.Middleware("/api/v1/clients/{client_id}/accounts/{account_id}/transactions", h.GetTransactions)).Methods("GET")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}/transactions", metrics.Middleware("/api/v1/clients/{client_id}/accounts/{account_id}/transactions", h.CreateTransaction)).Methods("POST")
+	a.Router.HandleFunc("/api/v1/clients/{client_id:[0-9]+}/accounts/{account_id:[0-9]+}/transactions/stream", events.StreamHandler(a.listener)).Methods("GET")
+
+	// Operational routes: liveness, readiness and Prometheus metrics, so
+	// a load balancer or Kubernetes can make real decisions instead of
+	// relying on the container restarting when Ping fails.
+	a.Router.HandleFunc("/healthz", a.handleHealthz).Methods("GET")
+	a.Router.HandleFunc("/readyz", a.handleReadyz).Methods("GET")
+	a.Router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
 	// Serving Documentation Web Server
 	// host:port/docs
@@ -50,89 +64,120 @@ func (a *App) initRoutes() {
 	a.Router.Handle("/docs", sh)
 }
 
+// handleHealthz reports process liveness: if this handler can run at
+// all, the process is alive. It never touches the DB or listener.
+func (a *App) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports whether the app can actually serve traffic: the
+// DB pool must answer a Ping and the LISTEN connection must be up.
+func (a *App) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := a.db.Ping(ctx); err != nil {
+		http.Error(w, fmt.Sprintf("db not ready: %s", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	if !a.listener.Healthy() {
+		http.Error(w, "event listener not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
 /*
-To initialize the routes and database connection you must
-include the following information as strings and also
-call Run setting the port to serve to the web.
+Initialize opens a pgxpool.Pool for the DB described by cfg and brings
+the schema up to date. It replaces the old sql.Open/Exec-the-seed-file
+flow: connections are now pooled and tuned via cfg, and the schema is
+advanced with the versioned migration runner in the migrations package
+instead of re-running a seed script on every boot.
 */
-func (a *App) Initialize(host, port, user, password, dbname string) {
-	// connectionStr := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local", user, password, host, port, dbname)
-	connectionStr := fmt.Sprintf(
-		"host=%s port=%v user=%s "+
-			"password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname,
-	)
-
-	var err error
-	a.db, err = sql.Open("pgx", connectionStr)
-	if err != nil {
+func (a *App) Initialize(ctx context.Context, cfg *config.Config) error {
+	a.cfg = cfg
 
-		u.LogInfo("Error opening a new connection to the DB.", err)
+	pool, err := db.NewPool(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("building DB pool: %w", err)
 	}
+	a.db = pool
 
 	a.Router = mux.NewRouter() // Make sure this is set before the server is started
 
-	// We try to validate the connection to the DB is correct, otherwise, the app
-	// will restart itself, this is a temporary solution because the postgres image usually
-	// is initialized after golang's image.
-	err = a.db.Ping()
-	if err != nil {
+	// Postgres usually comes up after the Go process in local/compose
+	// environments, so we retry the initial Ping with backoff instead of
+	// failing fast.
+	if err := db.WaitForReady(ctx, a.db); err != nil {
 		a.db.Close()
-		a.l.Fatal(err)
-	} else {
-		u.LogInfo("(Optional)", "Creating and seeding tables to initializate DB.")
-
-		// Executing SQL statements to create tables and seed DB.
-		sqlDir := "db/docker_postgres_init.sql"
-		query, err := ioutil.ReadFile(sqlDir)
-		if err != nil {
-			u.LogError(fmt.Sprintf("Error while reading %s file.", sqlDir), err)
-		}
+		return fmt.Errorf("waiting for DB: %w", err)
+	}
 
-		if _, err := a.db.Exec(string(query)); err != nil {
-			a.l.Panic("Unable to run SQL statements.", err)
-		}
+	u.LogInfo("(Optional)", "Applying pending migrations.")
+	if err := migrations.Run(ctx, a.db); err != nil {
+		a.db.Close()
+		return fmt.Errorf("running migrations: %w", err)
 	}
+
+	a.listener = events.NewListener(db.ConnectionString(cfg), a.l, cfg.DBAcquireTimeout, events.ChannelTransactionCreated, events.ChannelAccountUpdated)
+	a.listener.Start(ctx, &a.wg)
+
+	metrics.RegisterDBPoolStats(a.db)
+
+	return nil
 }
 
 /*
-Runs the new server.
+Run starts the server on addr and blocks until ctx is cancelled (by the
+SIGTERM/SIGINT handler installed in main), at which point it drains
+in-flight requests, waits for background workers (the event listener)
+to stop, and closes the DB pool before returning. addr is taken as a
+parameter rather than read off the App so operators can override the
+bind address (e.g. PORT or BIND_ADDR from config) at deploy time without
+recompiling.
 */
-func (a *App) Run() {
+func (a *App) Run(ctx context.Context, addr string) error {
 	// Initializing routes
 	a.initRoutes()
 
 	// Creating a new server
 	srv := http.Server{
-		Addr:         a.bindAddr,        // configure the bind address
-		Handler:      a.Router,          // set the default handler
-		ErrorLog:     a.l,               // set the logger for the server
-		ReadTimeout:  5 * time.Second,   // max time to read request from the client
-		WriteTimeout: 10 * time.Second,  // max time to write response to the client
-		IdleTimeout:  120 * time.Second, // max time for connections using TCP Keep-Alive
+		Addr:         addr,               // configure the bind address
+		Handler:      a.Router,           // set the default handler
+		ErrorLog:     a.l,                // set the logger for the server
+		ReadTimeout:  a.cfg.ReadTimeout,  // max time to read request from the client
+		WriteTimeout: a.cfg.WriteTimeout, // max time to write response to the client
+		IdleTimeout:  a.cfg.IdleTimeout,  // max time for connections using TCP Keep-Alive
 	}
 
 	// Starting the server
 	go func() {
-		u.LogInfo("Running server on port", a.bindAddr)
+		u.LogInfo("Running server on port", addr)
 
 		err := srv.ListenAndServe()
-		if err != nil {
+		if err != nil && err != http.ErrServerClosed {
 			a.l.Printf("Server Status: %s\n", err)
 			os.Exit(1)
 		}
 	}()
 
-	// Creating channel
-	cs := make(chan os.Signal, 1)
+	<-ctx.Done()
+	u.LogDebug("Signal received, shutting down.", ctx.Err())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
 
-	signal.Notify(cs, os.Interrupt, os.Kill)
-	// signal.Notify(sigchan, os.Kill) // If running on Windows
+	shutdownErr := srv.Shutdown(shutdownCtx)
 
-	sigchan := <-cs
-	u.LogDebug("Signal received:", sigchan)
+	// Background workers (currently just the event listener) watch the
+	// same ctx and stop on their own once it's cancelled; wait for them
+	// to actually exit before tearing down the pool they depend on.
+	a.wg.Wait()
+	a.db.Close()
 
-	ctx, fn := context.WithTimeout(context.Background(), 30*time.Second)
-	defer fn()
-	srv.Shutdown(ctx)
+	return shutdownErr
 }
@@ -0,0 +1,176 @@
+// Package config loads runtime configuration for the server from
+// environment variables, optionally seeded from a .env file.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds every value the server needs to boot: where to bind,
+// how to reach Postgres, and the HTTP timeouts to apply.
+type Config struct {
+	BindAddr string
+
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+
+	DBMinConns          int32
+	DBMaxConns          int32
+	DBHealthCheckPeriod time.Duration
+	DBMaxConnLifetime   time.Duration
+	DBAcquireTimeout    time.Duration
+
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// Default values applied when the corresponding environment variable is
+// absent. Only DB_USER, DB_PASSWORD and DB_NAME have no default since
+// they're required.
+const (
+	defaultBindAddr        = ":8080"
+	defaultDBHost          = "localhost"
+	defaultDBPort          = "5432"
+	defaultDBSSLMode       = "disable"
+	defaultReadTimeout     = 5 * time.Second
+	defaultWriteTimeout    = 10 * time.Second
+	defaultIdleTimeout     = 120 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+
+	defaultDBMinConns          = 2
+	defaultDBMaxConns          = 10
+	defaultDBHealthCheckPeriod = time.Minute
+	defaultDBMaxConnLifetime   = time.Hour
+	defaultDBAcquireTimeout    = 5 * time.Second
+)
+
+// Load reads a .env file if present (missing files are not an error) and
+// then builds a Config from the environment, applying defaults and
+// validating that the required DB credentials were supplied.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("config: reading .env file: %w", err)
+	}
+
+	cfg := &Config{
+		BindAddr: envOrDefault("BIND_ADDR", ""),
+
+		DBHost:     envOrDefault("DB_HOST", defaultDBHost),
+		DBPort:     envOrDefault("DB_PORT", defaultDBPort),
+		DBUser:     os.Getenv("DB_USER"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     os.Getenv("DB_NAME"),
+		DBSSLMode:  envOrDefault("DB_SSLMODE", defaultDBSSLMode),
+	}
+
+	if cfg.BindAddr == "" {
+		port := envOrDefault("PORT", "8080")
+		cfg.BindAddr = defaultBindAddr
+		if port != "8080" {
+			cfg.BindAddr = ":" + port
+		}
+	}
+
+	var err error
+	if cfg.ReadTimeout, err = durationOrDefault("READ_TIMEOUT", defaultReadTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.WriteTimeout, err = durationOrDefault("WRITE_TIMEOUT", defaultWriteTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.IdleTimeout, err = durationOrDefault("IDLE_TIMEOUT", defaultIdleTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownTimeout, err = durationOrDefault("SHUTDOWN_TIMEOUT", defaultShutdownTimeout); err != nil {
+		return nil, err
+	}
+
+	minConns, err := intOrDefault("DB_MIN_CONNS", defaultDBMinConns)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DBMinConns = int32(minConns)
+
+	maxConns, err := intOrDefault("DB_MAX_CONNS", defaultDBMaxConns)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DBMaxConns = int32(maxConns)
+
+	if cfg.DBHealthCheckPeriod, err = durationOrDefault("DB_HEALTH_CHECK_PERIOD", defaultDBHealthCheckPeriod); err != nil {
+		return nil, err
+	}
+	if cfg.DBMaxConnLifetime, err = durationOrDefault("DB_MAX_CONN_LIFETIME", defaultDBMaxConnLifetime); err != nil {
+		return nil, err
+	}
+	if cfg.DBAcquireTimeout, err = durationOrDefault("DB_ACQUIRE_TIMEOUT", defaultDBAcquireTimeout); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// validate ensures the required DB credentials were provided; everything
+// else has a usable default.
+func (c *Config) validate() error {
+	missing := make([]string, 0, 3)
+	if c.DBUser == "" {
+		missing = append(missing, "DB_USER")
+	}
+	if c.DBPassword == "" {
+		missing = append(missing, "DB_PASSWORD")
+	}
+	if c.DBName == "" {
+		missing = append(missing, "DB_NAME")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("config: missing required environment variable(s): %v", missing)
+	}
+	return nil
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func intOrDefault(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s must be an integer: %w", key, err)
+	}
+	return n, nil
+}
+
+func durationOrDefault(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config: %s must be an integer number of seconds: %w", key, err)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntOrDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		def     int
+		want    int
+		wantErr bool
+	}{
+		{name: "unset uses default", value: "", def: 10, want: 10},
+		{name: "valid integer overrides default", value: "5", def: 10, want: 5},
+		{name: "non-integer is an error", value: "nope", def: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value != "" {
+				t.Setenv("TEST_INT_OR_DEFAULT", tt.value)
+			}
+
+			got, err := intOrDefault("TEST_INT_OR_DEFAULT", tt.def)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("intOrDefault(%q) = %d, nil; want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("intOrDefault(%q) unexpected error: %s", tt.value, err)
+			}
+			if got != tt.want {
+				t.Fatalf("intOrDefault(%q) = %d; want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationOrDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		def     time.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset uses default", value: "", def: 5 * time.Second, want: 5 * time.Second},
+		{name: "valid seconds overrides default", value: "30", def: 5 * time.Second, want: 30 * time.Second},
+		{name: "non-integer is an error", value: "1m", def: 5 * time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value != "" {
+				t.Setenv("TEST_DURATION_OR_DEFAULT", tt.value)
+			}
+
+			got, err := durationOrDefault("TEST_DURATION_OR_DEFAULT", tt.def)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("durationOrDefault(%q) = %s, nil; want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("durationOrDefault(%q) unexpected error: %s", tt.value, err)
+			}
+			if got != tt.want {
+				t.Fatalf("durationOrDefault(%q) = %s; want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name:    "all required fields present",
+			cfg:     Config{DBUser: "u", DBPassword: "p", DBName: "n"},
+			wantErr: false,
+		},
+		{
+			name:    "missing DB_USER",
+			cfg:     Config{DBPassword: "p", DBName: "n"},
+			wantErr: true,
+		},
+		{
+			name:    "missing all required fields",
+			cfg:     Config{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr && err == nil {
+				t.Fatalf("validate() = nil; want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validate() unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("missing required env vars is an error", func(t *testing.T) {
+		t.Setenv("DB_USER", "")
+		t.Setenv("DB_PASSWORD", "")
+		t.Setenv("DB_NAME", "")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() = nil error; want error for missing DB credentials")
+		}
+	})
+
+	t.Run("applies defaults and honours PORT", func(t *testing.T) {
+		t.Setenv("DB_USER", "u")
+		t.Setenv("DB_PASSWORD", "p")
+		t.Setenv("DB_NAME", "n")
+		t.Setenv("BIND_ADDR", "")
+		t.Setenv("PORT", "9090")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %s", err)
+		}
+		if cfg.BindAddr != ":9090" {
+			t.Fatalf("cfg.BindAddr = %q; want %q", cfg.BindAddr, ":9090")
+		}
+		if cfg.DBHost != defaultDBHost {
+			t.Fatalf("cfg.DBHost = %q; want default %q", cfg.DBHost, defaultDBHost)
+		}
+		if cfg.DBAcquireTimeout != defaultDBAcquireTimeout {
+			t.Fatalf("cfg.DBAcquireTimeout = %s; want default %s", cfg.DBAcquireTimeout, defaultDBAcquireTimeout)
+		}
+	})
+
+	t.Run("BIND_ADDR takes precedence over PORT", func(t *testing.T) {
+		t.Setenv("DB_USER", "u")
+		t.Setenv("DB_PASSWORD", "p")
+		t.Setenv("DB_NAME", "n")
+		t.Setenv("BIND_ADDR", ":1234")
+		t.Setenv("PORT", "9090")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %s", err)
+		}
+		if cfg.BindAddr != ":1234" {
+			t.Fatalf("cfg.BindAddr = %q; want %q", cfg.BindAddr, ":1234")
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Package db builds and health-checks the pgxpool.Pool used to talk to
+// Postgres.
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brxyxn/go_mps_redcage/config"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ConnectionString builds the libpq-style DSN cfg describes. It's shared
+// by NewPool and by anything (like events.Listener) that needs to open
+// its own connection outside the pool.
+func ConnectionString(cfg *config.Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%v user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
+	)
+}
+
+// NewPool builds a pgxpool.Pool configured from cfg: min/max connections,
+// health check period and max connection lifetime are all tunable via
+// environment variables so operators can size the pool per deployment.
+// LazyConnect is set so ConnectConfig itself never blocks on or fails
+// over a Postgres that isn't up yet; WaitForReady is what actually
+// establishes and retries the first real connection.
+func NewPool(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(ConnectionString(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("db: parsing pool config: %w", err)
+	}
+
+	poolCfg.MinConns = cfg.DBMinConns
+	poolCfg.MaxConns = cfg.DBMaxConns
+	poolCfg.HealthCheckPeriod = cfg.DBHealthCheckPeriod
+	poolCfg.MaxConnLifetime = cfg.DBMaxConnLifetime
+	poolCfg.LazyConnect = true
+
+	pool, err := pgxpool.ConnectConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("db: connecting pool: %w", err)
+	}
+
+	return pool, nil
+}
+
+// Acquire checks out a connection from pool, bounding the wait by
+// timeout (cfg.DBAcquireTimeout) instead of letting callers block on the
+// pool indefinitely under load.
+func Acquire(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration) (*pgxpool.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := pool.Acquire(acquireCtx)
+	if err != nil {
+		return nil, fmt.Errorf("db: acquiring connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+// WaitForReady pings pool with exponential backoff until it succeeds or
+// ctx is done, instead of failing fast: Postgres usually comes up after
+// the Go process in local/compose environments, and a hard Fatal on the
+// first failed Ping just crash-loops the container until it wins the
+// race. Because NewPool uses LazyConnect, this Ping is what makes the
+// first real connection attempt, so the retry loop here actually covers
+// the race it's meant to.
+func WaitForReady(ctx context.Context, pool *pgxpool.Pool) error {
+	backoff := 250 * time.Millisecond
+	const maxBackoff = 10 * time.Second
+
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("db: giving up waiting for Postgres: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
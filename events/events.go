@@ -0,0 +1,42 @@
+// Package events subscribes to Postgres LISTEN/NOTIFY channels and fans
+// the decoded payloads out to in-process subscribers.
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Channel names the triggers created in migrations/sql/0002_notify_triggers.up.sql
+// publish to.
+const (
+	ChannelTransactionCreated = "transaction_created"
+	ChannelAccountUpdated     = "account_updated"
+)
+
+// Event is a decoded NOTIFY payload ready to hand to a subscriber or
+// write out over SSE.
+type Event struct {
+	Channel    string          `json:"channel"`
+	Payload    json.RawMessage `json:"payload"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// TransactionCreated is the payload shape emitted by
+// notify_transaction_created(). Amount is a json.Number because
+// json_build_object serializes the underlying NUMERIC column as a bare
+// JSON number, not a string.
+type TransactionCreated struct {
+	ID        int64       `json:"id"`
+	AccountID int64       `json:"account_id"`
+	Amount    json.Number `json:"amount"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// AccountUpdated is the payload shape emitted by notify_account_updated().
+// Balance is a json.Number for the same reason as TransactionCreated.Amount.
+type AccountUpdated struct {
+	ID       int64       `json:"id"`
+	ClientID int64       `json:"client_id"`
+	Balance  json.Number `json:"balance"`
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTransactionCreatedUnmarshal(t *testing.T) {
+	payload := `{"id":1,"account_id":2,"amount":123.45,"created_at":"2026-01-01T00:00:00Z"}`
+
+	var tx TransactionCreated
+	if err := json.Unmarshal([]byte(payload), &tx); err != nil {
+		t.Fatalf("unmarshal TransactionCreated: %s", err)
+	}
+
+	if tx.ID != 1 || tx.AccountID != 2 {
+		t.Fatalf("tx = %+v; want ID=1 AccountID=2", tx)
+	}
+	if tx.Amount.String() != "123.45" {
+		t.Fatalf("tx.Amount = %q; want %q", tx.Amount.String(), "123.45")
+	}
+}
+
+func TestAccountUpdatedUnmarshal(t *testing.T) {
+	payload := `{"id":1,"client_id":2,"balance":500}`
+
+	var au AccountUpdated
+	if err := json.Unmarshal([]byte(payload), &au); err != nil {
+		t.Fatalf("unmarshal AccountUpdated: %s", err)
+	}
+
+	if au.ID != 1 || au.ClientID != 2 {
+		t.Fatalf("au = %+v; want ID=1 ClientID=2", au)
+	}
+	if au.Balance.String() != "500" {
+		t.Fatalf("au.Balance = %q; want %q", au.Balance.String(), "500")
+	}
+}
@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Listener opens its own dedicated connection LISTENing on a fixed set
+// of channels and fans decoded notifications out to subscribers. It
+// deliberately does not go through the shared pgxpool.Pool: a connection
+// that's issued LISTEN is tainted at the Postgres session level, and
+// handing it back to the pool would let an unrelated handler query reuse
+// a connection still subscribed to these channels.
+type Listener struct {
+	connString     string
+	l              *log.Logger
+	channels       []string
+	connectTimeout time.Duration
+
+	mu          sync.Mutex
+	subscribers map[string][]chan Event
+	healthy     bool
+}
+
+// NewListener builds a Listener that will connect and subscribe to
+// channels once Start is called. connString is the same DSN the pool is
+// built from (db.ConnectionString(cfg)); connectTimeout bounds how long
+// each (re)connect attempt waits (cfg.DBAcquireTimeout).
+func NewListener(connString string, l *log.Logger, connectTimeout time.Duration, channels ...string) *Listener {
+	return &Listener{
+		connString:     connString,
+		l:              l,
+		channels:       channels,
+		connectTimeout: connectTimeout,
+		subscribers:    make(map[string][]chan Event),
+	}
+}
+
+// Subscribe returns a channel that receives every Event published on
+// channel, plus an unsubscribe func the caller must call when done.
+func (ls *Listener) Subscribe(channel string) (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	ls.mu.Lock()
+	ls.subscribers[channel] = append(ls.subscribers[channel], ch)
+	ls.mu.Unlock()
+
+	unsubscribe := func() {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		subs := ls.subscribers[channel]
+		for i, sub := range subs {
+			if sub == ch {
+				ls.subscribers[channel] = append(subs[:i], subs[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Healthy reports whether the listener currently holds a live LISTEN
+// connection, for use in readiness probes.
+func (ls *Listener) Healthy() bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.healthy
+}
+
+// Start runs the listen loop until ctx is cancelled, reconnecting with
+// exponential backoff whenever the underlying connection is lost. It
+// increments wg before returning and calls wg.Done when the loop exits,
+// so callers can wait for a clean shutdown.
+func (ls *Listener) Start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		const minBackoff = 500 * time.Millisecond
+		const maxBackoff = 30 * time.Second
+		backoff := minBackoff
+
+		for ctx.Err() == nil {
+			if err := ls.listenOnce(ctx); err != nil {
+				ls.setHealthy(false)
+				ls.l.Printf("events: listener disconnected, reconnecting in %s: %s", backoff, err)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+
+				backoff *= 2
+				if backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+				continue
+			}
+			backoff = minBackoff
+		}
+	}()
+}
+
+// listenOnce opens a new dedicated connection, issues LISTEN for every
+// channel, and blocks handing off notifications until ctx is cancelled
+// or the connection fails. The connection is closed outright rather than
+// released back to a pool, so a still-subscribed session never ends up
+// serving an unrelated query.
+func (ls *Listener) listenOnce(ctx context.Context) error {
+	connectCtx, cancel := context.WithTimeout(ctx, ls.connectTimeout)
+	conn, err := pgx.Connect(connectCtx, ls.connString)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("connecting listen connection: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	for _, channel := range ls.channels {
+		if _, err := conn.Exec(ctx, fmt.Sprintf("LISTEN %s", channel)); err != nil {
+			return fmt.Errorf("listening on %s: %w", channel, err)
+		}
+	}
+
+	ls.setHealthy(true)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("waiting for notification: %w", err)
+		}
+
+		ls.publish(Event{
+			Channel:    notification.Channel,
+			Payload:    json.RawMessage(notification.Payload),
+			ReceivedAt: time.Now(),
+		})
+	}
+}
+
+func (ls *Listener) setHealthy(healthy bool) {
+	ls.mu.Lock()
+	ls.healthy = healthy
+	ls.mu.Unlock()
+}
+
+func (ls *Listener) publish(ev Event) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	for _, sub := range ls.subscribers[ev.Channel] {
+		select {
+		case sub <- ev:
+		default:
+			ls.l.Printf("events: subscriber channel for %s is full, dropping event", ev.Channel)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// StreamHandler returns an http.HandlerFunc that streams
+// ChannelTransactionCreated events for the account_id in the URL as
+// server-sent events. It's meant to be registered at
+// GET /api/v1/clients/{client_id}/accounts/{account_id}/transactions/stream.
+func StreamHandler(ls *Listener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, err := strconv.ParseInt(mux.Vars(r)["account_id"], 10, 64)
+		if err != nil {
+			http.Error(w, "invalid account_id", http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		ch, unsubscribe := ls.Subscribe(ChannelTransactionCreated)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				tx, ok := decodeTransactionForAccount(ev.Payload, accountID)
+				if !ok {
+					continue
+				}
+
+				payload, err := json.Marshal(tx)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Channel, payload)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// decodeTransactionForAccount unmarshals payload as a TransactionCreated
+// and reports whether it belongs to accountID. It returns false both on a
+// decode error and on an account mismatch, since StreamHandler treats
+// both cases the same way: skip the notification.
+func decodeTransactionForAccount(payload json.RawMessage, accountID int64) (TransactionCreated, bool) {
+	var tx TransactionCreated
+	if err := json.Unmarshal(payload, &tx); err != nil {
+		return TransactionCreated{}, false
+	}
+	if tx.AccountID != accountID {
+		return TransactionCreated{}, false
+	}
+	return tx, true
+}
@@ -0,0 +1,43 @@
+package events
+
+import "testing"
+
+func TestDecodeTransactionForAccount(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		accountID int64
+		wantOK    bool
+	}{
+		{
+			name:      "matching account decodes",
+			payload:   `{"id":1,"account_id":42,"amount":10.5,"created_at":"2026-01-01T00:00:00Z"}`,
+			accountID: 42,
+			wantOK:    true,
+		},
+		{
+			name:      "different account is filtered out",
+			payload:   `{"id":1,"account_id":42,"amount":10.5,"created_at":"2026-01-01T00:00:00Z"}`,
+			accountID: 7,
+			wantOK:    false,
+		},
+		{
+			name:      "invalid JSON is filtered out",
+			payload:   `not json`,
+			accountID: 42,
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := decodeTransactionForAccount([]byte(tt.payload), tt.accountID)
+			if ok != tt.wantOK {
+				t.Fatalf("decodeTransactionForAccount() ok = %v; want %v", ok, tt.wantOK)
+			}
+			if ok && tx.AccountID != tt.accountID {
+				t.Fatalf("tx.AccountID = %d; want %d", tx.AccountID, tt.accountID)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Package handlers implements the HTTP handlers backing the
+// /api/v1/clients/... routes registered in App.initRoutes.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brxyxn/go_mps_redcage/db"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Handlers holds the dependencies every route handler needs: the DB
+// pool to query and a logger for request-handling errors.
+type Handlers struct {
+	pool           *pgxpool.Pool
+	l              *log.Logger
+	acquireTimeout time.Duration
+}
+
+// NewHandlers builds a Handlers backed by pool, querying and executing
+// everything through pool's context-aware methods so every call is
+// bound to the request that triggered it. acquireTimeout
+// (cfg.DBAcquireTimeout) bounds how long a handler will wait to check a
+// connection out of pool for a multi-statement transaction.
+func NewHandlers(pool *pgxpool.Pool, l *log.Logger, acquireTimeout time.Duration) *Handlers {
+	return &Handlers{pool: pool, l: l, acquireTimeout: acquireTimeout}
+}
+
+type client struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type account struct {
+	ID        int64     `json:"id"`
+	ClientID  int64     `json:"client_id"`
+	Balance   string    `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type transaction struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    string    `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateClient handles POST /api/v1/clients.
+func (h *Handlers) CreateClient(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.Email == "" {
+		http.Error(w, "name and email are required", http.StatusBadRequest)
+		return
+	}
+
+	var c client
+	c.Name, c.Email = req.Name, req.Email
+	err := h.pool.QueryRow(r.Context(),
+		`INSERT INTO clients (name, email) VALUES ($1, $2) RETURNING id, created_at`,
+		req.Name, req.Email,
+	).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		h.l.Printf("handlers: creating client: %s", err)
+		http.Error(w, "unable to create client", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// GetClient handles GET /api/v1/clients/{client_id}.
+func (h *Handlers) GetClient(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseInt(mux.Vars(r)["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+
+	var c client
+	err = h.pool.QueryRow(r.Context(),
+		`SELECT id, name, email, created_at FROM clients WHERE id = $1`, clientID,
+	).Scan(&c.ID, &c.Name, &c.Email, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "client not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.l.Printf("handlers: getting client %d: %s", clientID, err)
+		http.Error(w, "unable to get client", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+// GetAccounts handles GET /api/v1/clients/{client_id}/accounts.
+func (h *Handlers) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseInt(mux.Vars(r)["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := h.pool.Query(r.Context(),
+		`SELECT id, client_id, balance, created_at FROM accounts WHERE client_id = $1 ORDER BY id`, clientID,
+	)
+	if err != nil {
+		h.l.Printf("handlers: listing accounts for client %d: %s", clientID, err)
+		http.Error(w, "unable to list accounts", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	accounts := []account{}
+	for rows.Next() {
+		var a account
+		if err := rows.Scan(&a.ID, &a.ClientID, &a.Balance, &a.CreatedAt); err != nil {
+			h.l.Printf("handlers: scanning account row: %s", err)
+			http.Error(w, "unable to list accounts", http.StatusInternalServerError)
+			return
+		}
+		accounts = append(accounts, a)
+	}
+
+	writeJSON(w, http.StatusOK, accounts)
+}
+
+// CreateAccount handles POST /api/v1/clients/{client_id}/accounts.
+func (h *Handlers) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	clientID, err := strconv.ParseInt(mux.Vars(r)["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Balance string `json:"balance"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Balance == "" {
+		req.Balance = "0"
+	}
+
+	a := account{ClientID: clientID, Balance: req.Balance}
+	err = h.pool.QueryRow(r.Context(),
+		`INSERT INTO accounts (client_id, balance) VALUES ($1, $2) RETURNING id, created_at`,
+		clientID, req.Balance,
+	).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		h.l.Printf("handlers: creating account for client %d: %s", clientID, err)
+		http.Error(w, "unable to create account", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// GetAccount handles GET /api/v1/clients/{client_id}/accounts/{account_id}.
+func (h *Handlers) GetAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID, err := strconv.ParseInt(vars["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+	accountID, err := strconv.ParseInt(vars["account_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid account_id", http.StatusBadRequest)
+		return
+	}
+
+	var a account
+	err = h.pool.QueryRow(r.Context(),
+		`SELECT id, client_id, balance, created_at FROM accounts WHERE id = $1 AND client_id = $2`,
+		accountID, clientID,
+	).Scan(&a.ID, &a.ClientID, &a.Balance, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.l.Printf("handlers: getting account %d: %s", accountID, err)
+		http.Error(w, "unable to get account", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a)
+}
+
+// GetTransactions handles
+// GET /api/v1/clients/{client_id}/accounts/{account_id}/transactions.
+func (h *Handlers) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID, err := strconv.ParseInt(vars["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+	accountID, err := strconv.ParseInt(vars["account_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid account_id", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := h.pool.Query(r.Context(), `
+		SELECT t.id, t.account_id, t.amount, t.created_at
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.account_id = $1 AND a.client_id = $2
+		ORDER BY t.id
+	`, accountID, clientID)
+	if err != nil {
+		h.l.Printf("handlers: listing transactions for account %d: %s", accountID, err)
+		http.Error(w, "unable to list transactions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	transactions := []transaction{}
+	for rows.Next() {
+		var t transaction
+		if err := rows.Scan(&t.ID, &t.AccountID, &t.Amount, &t.CreatedAt); err != nil {
+			h.l.Printf("handlers: scanning transaction row: %s", err)
+			http.Error(w, "unable to list transactions", http.StatusInternalServerError)
+			return
+		}
+		transactions = append(transactions, t)
+	}
+
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+// CreateTransaction handles
+// POST /api/v1/clients/{client_id}/accounts/{account_id}/transactions.
+func (h *Handlers) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID, err := strconv.ParseInt(vars["client_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+	accountID, err := strconv.ParseInt(vars["account_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid account_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Amount string `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount == "" {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Inserting the transaction and crediting the account balance must
+	// happen atomically and on a connection bound to this request's
+	// context, not as two independent pool.Exec calls that could leave
+	// the balance out of sync if the request is cancelled in between.
+	conn, err := db.Acquire(r.Context(), h.pool, h.acquireTimeout)
+	if err != nil {
+		h.l.Printf("handlers: acquiring connection for transaction on account %d: %s", accountID, err)
+		http.Error(w, "unable to create transaction", http.StatusServiceUnavailable)
+		return
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(r.Context())
+	if err != nil {
+		h.l.Printf("handlers: beginning transaction for account %d: %s", accountID, err)
+		http.Error(w, "unable to create transaction", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	t := transaction{AccountID: accountID, Amount: req.Amount}
+	err = tx.QueryRow(r.Context(), `
+		INSERT INTO transactions (account_id, amount)
+		SELECT id, $2 FROM accounts WHERE id = $1 AND client_id = $3
+		RETURNING id, created_at
+	`, accountID, req.Amount, clientID).Scan(&t.ID, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "account not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.l.Printf("handlers: creating transaction for account %d: %s", accountID, err)
+		http.Error(w, "unable to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(r.Context(),
+		`UPDATE accounts SET balance = balance + $1 WHERE id = $2`, req.Amount, accountID,
+	); err != nil {
+		h.l.Printf("handlers: updating balance for account %d: %s", accountID, err)
+		http.Error(w, "unable to update account balance", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		h.l.Printf("handlers: committing transaction for account %d: %s", accountID, err)
+		http.Error(w, "unable to create transaction", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(w, `{"error":"encoding response failed"}`)
+	}
+}
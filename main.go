@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/brxyxn/go_mps_redcage/config"
+	"github.com/brxyxn/go_mps_redcage/db"
+	"github.com/brxyxn/go_mps_redcage/migrations"
+)
+
+func main() {
+	rollback := flag.Bool("rollback", false, "roll back the most recently applied migration and exit, instead of starting the server")
+	flag.Parse()
+
+	l := log.New(os.Stdout, "go_mps_redcage ", log.LstdFlags)
+
+	cfg, err := config.Load()
+	if err != nil {
+		l.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *rollback {
+		runRollback(ctx, l, cfg)
+		return
+	}
+
+	a := App{l: l}
+	if err := a.Initialize(ctx, cfg); err != nil {
+		l.Fatal(err)
+	}
+
+	if err := a.Run(ctx, cfg.BindAddr); err != nil {
+		l.Fatal(err)
+	}
+}
+
+// runRollback opens a short-lived pool, rolls back the most recently
+// applied migration, and exits. It deliberately doesn't go through
+// App.Initialize: rolling back shouldn't start the event listener or HTTP
+// server.
+func runRollback(ctx context.Context, l *log.Logger, cfg *config.Config) {
+	pool, err := db.NewPool(ctx, cfg)
+	if err != nil {
+		l.Fatalf("rollback: building DB pool: %s", err)
+	}
+	defer pool.Close()
+
+	if err := db.WaitForReady(ctx, pool); err != nil {
+		l.Fatalf("rollback: waiting for DB: %s", err)
+	}
+
+	if err := migrations.Rollback(ctx, pool); err != nil {
+		l.Fatalf("rollback: %s", err)
+	}
+
+	l.Print("rollback: done")
+}
@@ -0,0 +1,103 @@
+// Package metrics exposes Prometheus instrumentation for the HTTP
+// routes and the DB pool: request counts, latency histograms, in-flight
+// gauges, and pgxpool.Stat() gauges and counters.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests handled, labeled by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, labeled by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	requestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	})
+)
+
+// Middleware wraps next, recording request count, latency and in-flight
+// gauges for every request it serves. route should be the matched route
+// template (e.g. from mux.CurrentRoute) rather than the raw path, so
+// templated segments like {client_id} don't explode the label
+// cardinality.
+func Middleware(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestsInFlight.Inc()
+		defer requestsInFlight.Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(rec, r)
+
+		requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		requestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+	}
+}
+
+// statusRecorder captures the status code written by the wrapped
+// handler so it can be exported as a label.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// RegisterDBPoolStats registers a collector that reads pool.Stat() on
+// every scrape and exports it as a set of gauges, plus counters for the
+// cumulative acquire count and acquire duration.
+func RegisterDBPoolStats(pool *pgxpool.Pool) {
+	prometheus.MustRegister(&dbPoolCollector{pool: pool})
+}
+
+type dbPoolCollector struct {
+	pool *pgxpool.Pool
+}
+
+var (
+	dbPoolAcquiredConns   = prometheus.NewDesc("db_pool_acquired_conns", "Connections currently acquired from the pool.", nil, nil)
+	dbPoolIdleConns       = prometheus.NewDesc("db_pool_idle_conns", "Connections currently idle in the pool.", nil, nil)
+	dbPoolTotalConns      = prometheus.NewDesc("db_pool_total_conns", "Total connections currently open in the pool.", nil, nil)
+	dbPoolMaxConns        = prometheus.NewDesc("db_pool_max_conns", "Maximum connections the pool is configured to open.", nil, nil)
+	dbPoolAcquireCount    = prometheus.NewDesc("db_pool_acquire_count", "Total number of successful acquires from the pool.", nil, nil)
+	dbPoolAcquireDuration = prometheus.NewDesc("db_pool_acquire_duration_seconds_total", "Total time spent acquiring connections from the pool.", nil, nil)
+)
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbPoolAcquiredConns
+	ch <- dbPoolIdleConns
+	ch <- dbPoolTotalConns
+	ch <- dbPoolMaxConns
+	ch <- dbPoolAcquireCount
+	ch <- dbPoolAcquireDuration
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(dbPoolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(dbPoolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(dbPoolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(dbPoolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(dbPoolAcquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(dbPoolAcquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
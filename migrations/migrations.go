@@ -0,0 +1,191 @@
+// Package migrations applies the embedded SQL migration files to a
+// Postgres database, tracking what has already run in a
+// schema_migrations table so restarts don't re-apply them.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// migration is one versioned schema change: an Up statement to apply it
+// and a Down statement to roll it back.
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// load reads every file under sql/ and groups the up/down pair for each
+// version, returned sorted by version ascending.
+func load() ([]migration, error) {
+	entries, err := fs.ReadDir(sqlFiles, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: reading embedded sql dir: %w", err)
+	}
+
+	byVersion := map[int]*migration{}
+	for _, entry := range entries {
+		m := fileNamePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migrations: unexpected file name %q", entry.Name())
+		}
+
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("migrations: parsing version from %q: %w", entry.Name(), err)
+		}
+
+		contents, err := sqlFiles.ReadFile("sql/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrations: reading %q: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(contents)
+		} else {
+			mig.Down = string(contents)
+		}
+	}
+
+	migs := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		migs = append(migs, *mig)
+	}
+	sort.Slice(migs, func(i, j int) bool { return migs[i].Version < migs[j].Version })
+
+	return migs, nil
+}
+
+// Run applies every migration that hasn't been recorded in
+// schema_migrations yet, each in its own transaction. It is safe to call
+// on every boot: migrations that already ran are skipped.
+func Run(ctx context.Context, pool *pgxpool.Pool) error {
+	migs, err := load()
+	if err != nil {
+		return err
+	}
+
+	if _, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version     INTEGER PRIMARY KEY,
+			name        TEXT NOT NULL,
+			applied_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("migrations: creating schema_migrations table: %w", err)
+	}
+
+	for _, mig := range migs {
+		var applied bool
+		if err := pool.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, mig.Version,
+		).Scan(&applied); err != nil {
+			return fmt.Errorf("migrations: checking version %d: %w", mig.Version, err)
+		}
+		if applied {
+			continue
+		}
+
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("migrations: beginning transaction for version %d: %w", mig.Version, err)
+		}
+
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("migrations: applying version %d (%s): %w", mig.Version, mig.Name, err)
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name,
+		); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("migrations: recording version %d: %w", mig.Version, err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("migrations: committing version %d: %w", mig.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// Rollback reverts the most recently applied migration by running its
+// Down SQL and removing its schema_migrations row, both in a single
+// transaction. It is meant to be invoked explicitly by an operator (see
+// the -rollback flag in main.go), not run automatically on boot.
+func Rollback(ctx context.Context, pool *pgxpool.Pool) error {
+	migs, err := load()
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[int]migration, len(migs))
+	for _, mig := range migs {
+		byVersion[mig.Version] = mig
+	}
+
+	var version int
+	var name string
+	err = pool.QueryRow(ctx,
+		`SELECT version, name FROM schema_migrations ORDER BY version DESC LIMIT 1`,
+	).Scan(&version, &name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("migrations: no applied migrations to roll back")
+	}
+	if err != nil {
+		return fmt.Errorf("migrations: finding latest applied migration: %w", err)
+	}
+
+	mig, ok := byVersion[version]
+	if !ok {
+		return fmt.Errorf("migrations: no embedded migration files found for applied version %d (%s)", version, name)
+	}
+	if mig.Down == "" {
+		return fmt.Errorf("migrations: version %d (%s) has no down migration", version, name)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("migrations: beginning rollback transaction for version %d: %w", version, err)
+	}
+
+	if _, err := tx.Exec(ctx, mig.Down); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("migrations: rolling back version %d (%s): %w", version, name, err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, version); err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("migrations: removing schema_migrations row for version %d: %w", version, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("migrations: committing rollback for version %d: %w", version, err)
+	}
+
+	return nil
+}
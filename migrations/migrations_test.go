@@ -0,0 +1,37 @@
+package migrations
+
+import "testing"
+
+func TestLoad(t *testing.T) {
+	migs, err := load()
+	if err != nil {
+		t.Fatalf("load() unexpected error: %s", err)
+	}
+
+	if len(migs) != 2 {
+		t.Fatalf("load() returned %d migrations; want 2", len(migs))
+	}
+
+	wantVersions := []int{1, 2}
+	for i, mig := range migs {
+		if mig.Version != wantVersions[i] {
+			t.Fatalf("migs[%d].Version = %d; want %d", i, mig.Version, wantVersions[i])
+		}
+		if mig.Name == "" {
+			t.Fatalf("migs[%d].Name is empty", i)
+		}
+		if mig.Up == "" {
+			t.Fatalf("migs[%d] (%s) has no Up SQL", i, mig.Name)
+		}
+		if mig.Down == "" {
+			t.Fatalf("migs[%d] (%s) has no Down SQL", i, mig.Name)
+		}
+	}
+
+	if migs[0].Name != "init" {
+		t.Fatalf("migs[0].Name = %q; want %q", migs[0].Name, "init")
+	}
+	if migs[1].Name != "notify_triggers" {
+		t.Fatalf("migs[1].Name = %q; want %q", migs[1].Name, "notify_triggers")
+	}
+}